@@ -0,0 +1,209 @@
+// Package humanize formats byte counts for humans in either IEC
+// (KiB/MiB, 1024-based) or SI (kB/MB, 1000-based) units, with a
+// bounded, concurrency-safe cache and a Parse for the inverse
+// direction.
+package humanize
+
+import (
+	"container/list"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Mode selects the base a Formatter converts byte counts in.
+type Mode int
+
+const (
+	// IEC formats using 1024-based KiB/MiB/GiB/TiB/PiB, the same base
+	// the old memoizeBytes used (it labelled the units KB/MB).
+	IEC Mode = iota
+	// SI formats using 1000-based kB/MB/GB/TB/PB.
+	SI
+)
+
+// maxCacheEntries bounds the Formatter LRU so long file listings with
+// many distinct sizes can't grow the cache without limit.
+const maxCacheEntries = 4096
+
+var iecSuffixes = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+var siSuffixes = []string{"B", "kB", "MB", "GB", "TB", "PB"}
+
+func (m Mode) base() float64 {
+	if m == SI {
+		return 1000
+	}
+	return 1024
+}
+
+func (m Mode) suffixes() []string {
+	if m == SI {
+		return siSuffixes
+	}
+	return iecSuffixes
+}
+
+// ModeFromString parses the --units flag / DRIVE_UNITS env var value.
+func ModeFromString(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "iec", "binary":
+		return IEC, nil
+	case "si", "decimal":
+		return SI, nil
+	default:
+		return IEC, fmt.Errorf("humanize: unknown units mode %q", s)
+	}
+}
+
+type entry struct {
+	key int64
+	val string
+}
+
+// Formatter renders byte counts as human-readable strings, caching
+// the formatted form of values at or above its unit threshold in a
+// bounded, mutex-guarded LRU.
+type Formatter struct {
+	mode    Mode
+	grouped bool
+
+	mu    sync.RWMutex
+	cache map[int64]*list.Element
+	order *list.List
+}
+
+// New returns a Formatter for mode. When grouped is true, byte counts
+// under the unit threshold get locale-aware thousands separators.
+func New(mode Mode, grouped bool) *Formatter {
+	return &Formatter{
+		mode:    mode,
+		grouped: grouped,
+		cache:   make(map[int64]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Format renders b using f's mode, e.g. "1.50MiB" or "1.50MB".
+func (f *Formatter) Format(b int64) string {
+	if float64(b) < f.mode.base() {
+		// Fast, lock-free path: small values never touch the cache.
+		return f.formatSmall(b)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if el, ok := f.cache[b]; ok {
+		f.order.MoveToFront(el)
+		return el.Value.(*entry).val
+	}
+
+	val := f.compute(b)
+	el := f.order.PushFront(&entry{key: b, val: val})
+	f.cache[b] = el
+	if f.order.Len() > maxCacheEntries {
+		oldest := f.order.Back()
+		if oldest != nil {
+			f.order.Remove(oldest)
+			delete(f.cache, oldest.Value.(*entry).key)
+		}
+	}
+	return val
+}
+
+// formatSmall renders a sub-threshold b without touching the cache.
+// The grouped path still allocates through message.Printer, but the
+// common ungrouped path builds into a stack buffer and converts to a
+// string once, instead of FormatInt allocating one string and the "B"
+// concatenation allocating a second.
+func (f *Formatter) formatSmall(b int64) string {
+	if f.grouped {
+		return groupDigits(b) + "B"
+	}
+	var buf [20]byte
+	out := strconv.AppendInt(buf[:0], b, 10)
+	out = append(out, 'B')
+	return string(out)
+}
+
+func (f *Formatter) compute(b int64) string {
+	suffixes := f.mode.suffixes()
+	maxLen := len(suffixes) - 1
+	base := f.mode.base()
+
+	bf := float64(b)
+	i := 0
+	for {
+		if bf/base < 1 || i >= maxLen {
+			return fmt.Sprintf("%.2f%s", bf, suffixes[i])
+		}
+		bf /= base
+		i += 1
+	}
+}
+
+func groupDigits(n int64) string {
+	return message.NewPrinter(language.English).Sprintf("%d", n)
+}
+
+// defaultFormatter backs the package-level Bytes/SetMode helpers,
+// mirroring the old package-level prettyBytes var.
+var defaultFormatter = New(IEC, false)
+
+// SetMode reconfigures the default Formatter, e.g. from the --units
+// flag or the DRIVE_UNITS environment variable.
+func SetMode(mode Mode) {
+	defaultFormatter = New(mode, defaultFormatter.grouped)
+}
+
+// SetGrouped toggles locale-aware digit grouping on the default
+// Formatter's small-value fast path.
+func SetGrouped(grouped bool) {
+	defaultFormatter = New(defaultFormatter.mode, grouped)
+}
+
+// Bytes formats b using the default Formatter.
+func Bytes(b int64) string {
+	return defaultFormatter.Format(b)
+}
+
+// unitMultipliers is checked longest-suffix-first so "GiB" is matched
+// before the bare "G" fallback.
+var unitMultipliers = []struct {
+	suffix string
+	mult   float64
+}{
+	{"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+	{"PB", 1e15}, {"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+	{"P", 1 << 50}, {"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// Parse accepts values like "1.5GiB", "1.5GB" and "512K" and returns
+// the corresponding byte count, for use by quota/threshold flags.
+func Parse(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("humanize: empty value")
+	}
+
+	for _, u := range unitMultipliers {
+		if !strings.HasSuffix(s, u.suffix) {
+			continue
+		}
+		numPart := strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+		if numPart == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(numPart, 64)
+		if err != nil {
+			return 0, fmt.Errorf("humanize: invalid value %q: %v", s, err)
+		}
+		return int64(f * u.mult), nil
+	}
+	return 0, fmt.Errorf("humanize: unrecognized unit in %q", s)
+}
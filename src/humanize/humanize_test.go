@@ -0,0 +1,92 @@
+package humanize
+
+import "testing"
+
+func TestFormatIECAndSI(t *testing.T) {
+	cases := []struct {
+		mode Mode
+		b    int64
+		want string
+	}{
+		{IEC, 1024, "1.00KiB"},
+		{IEC, 1536, "1.50KiB"},
+		{SI, 1000, "1.00kB"},
+		{SI, 1500, "1.50kB"},
+		{IEC, 512, "512B"},
+	}
+	for _, c := range cases {
+		f := New(c.mode, false)
+		if got := f.Format(c.b); got != c.want {
+			t.Errorf("Format(%d) in mode %v = %q, want %q", c.b, c.mode, got, c.want)
+		}
+	}
+}
+
+func TestFormatSmallGrouped(t *testing.T) {
+	f := New(IEC, true)
+	if got := f.Format(1023); got != "1,023B" {
+		t.Errorf("grouped Format(1023) = %q, want %q", got, "1,023B")
+	}
+}
+
+func TestModeFromString(t *testing.T) {
+	cases := map[string]Mode{
+		"":     IEC,
+		"iec":  IEC,
+		"IEC":  IEC,
+		"si":   SI,
+		"SI":   SI,
+		"SI  ": SI,
+	}
+	for s, want := range cases {
+		got, err := ModeFromString(s)
+		if err != nil {
+			t.Fatalf("ModeFromString(%q): %v", s, err)
+		}
+		if got != want {
+			t.Errorf("ModeFromString(%q) = %v, want %v", s, got, want)
+		}
+	}
+	if _, err := ModeFromString("bogus"); err == nil {
+		t.Error("expected error for unknown units mode")
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+	}{
+		{"512K", 512 * 1 << 10},
+		{"1.5GiB", int64(1.5 * (1 << 30))},
+		{"1.5GB", int64(1.5 * 1e9)},
+		{"0B", 0},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.in, err)
+		}
+		if got != c.want {
+			t.Errorf("Parse(%q) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{"", "nope", "GiB"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) expected an error", in)
+		}
+	}
+}
+
+func TestFormatterCacheBounded(t *testing.T) {
+	f := New(IEC, false)
+	for i := int64(0); i < maxCacheEntries+100; i++ {
+		f.Format(1024 + i)
+	}
+	if f.order.Len() > maxCacheEntries {
+		t.Errorf("cache grew to %d entries, want <= %d", f.order.Len(), maxCacheEntries)
+	}
+}
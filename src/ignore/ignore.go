@@ -0,0 +1,260 @@
+// Package ignore implements gitignore(5)-style matching for
+// .driveignore files: nested per-directory files, negation, glob and
+// "**" patterns, directory-only patterns, and a case-insensitive
+// mode.
+package ignore
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/richthofen911/drive/src/paths"
+)
+
+// IgnoreFileName is the conventional per-directory ignore file name.
+const IgnoreFileName = ".driveignore"
+
+// pattern is one compiled line of an ignore file.
+type pattern struct {
+	negate   bool
+	dirOnly  bool
+	anchored bool
+	// base is the slash-separated directory the pattern is relative
+	// to, empty for the root.
+	base string
+	re   *regexp.Regexp
+}
+
+// Matcher answers whether a path should be ignored, according to a
+// stack of ignore files loaded from the root of a tree down to each
+// directory visited, plus a global per-user file.
+type Matcher struct {
+	caseInsensitive bool
+	patterns        []pattern
+	loaded          map[string]bool
+}
+
+// New returns an empty Matcher. Load it with LoadFile/LoadGlobal
+// before calling Match.
+func New(caseInsensitive bool) *Matcher {
+	return &Matcher{caseInsensitive: caseInsensitive, loaded: make(map[string]bool)}
+}
+
+// LoadGlobal loads the user's global ignore file, resolved through
+// drive/paths (legacy .gd/ first, then the XDG config dir, then
+// /etc/drive). A missing global file is not an error.
+func (m *Matcher) LoadGlobal() error {
+	p, err := paths.Resolve(IgnoreFileName)
+	if err != nil {
+		return nil
+	}
+	return m.LoadFile(p, "")
+}
+
+// LoadTree loads every IgnoreFileName found between root and dir
+// (inclusive), shallowest first, so that patterns in a deeper
+// directory's ignore file take precedence over its ancestors', just
+// as gitignore(5) specifies.
+func (m *Matcher) LoadTree(root, dir string) error {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	if rel == "." {
+		return m.loadDirFile(root, "")
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if err := m.loadDirFile(root, ""); err != nil {
+		return err
+	}
+	cur := ""
+	for _, part := range parts {
+		cur = path.Join(cur, part)
+		if err := m.loadDirFile(root, cur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Matcher) loadDirFile(root, relDir string) error {
+	full := filepath.Join(root, filepath.FromSlash(relDir), IgnoreFileName)
+	if _, err := os.Stat(full); err != nil {
+		return nil
+	}
+	return m.LoadFile(full, relDir)
+}
+
+// LoadFile loads and compiles the ignore file at p, whose patterns are
+// relative to baseDir (slash-separated, relative to the matcher's
+// root; "" for the root itself). A p already loaded by an earlier
+// call is skipped, so repeatedly walking into the same directory (as
+// LoadTree does once per directory visited) doesn't re-parse and
+// re-append the same ancestors' patterns every time.
+func (m *Matcher) LoadFile(p, baseDir string) error {
+	if m.loaded == nil {
+		m.loaded = make(map[string]bool)
+	}
+	if m.loaded[p] {
+		return nil
+	}
+
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	m.loaded[p] = true
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		compiled, ok := compileLine(line, baseDir, m.caseInsensitive)
+		if !ok {
+			continue
+		}
+		m.patterns = append(m.patterns, compiled)
+	}
+	return scanner.Err()
+}
+
+// Match reports whether path (slash-separated, relative to the
+// matcher's root) should be ignored. isDir tells directory-only
+// patterns ("build/") whether they apply. Later-loaded, more specific
+// patterns win over earlier ones, and a negated pattern ("!foo") can
+// re-include something an earlier pattern excluded, matching
+// gitignore(5) precedence.
+func (m *Matcher) Match(p string, isDir bool) bool {
+	p = filepath.ToSlash(p)
+	ignored := false
+	for _, pat := range m.patterns {
+		if pat.dirOnly && !isDir {
+			continue
+		}
+		if !pat.matches(p) {
+			continue
+		}
+		ignored = !pat.negate
+	}
+	return ignored
+}
+
+func (pat pattern) matches(p string) bool {
+	candidate := p
+	if pat.base != "" {
+		if !strings.HasPrefix(p, pat.base+"/") {
+			return false
+		}
+		candidate = strings.TrimPrefix(p, pat.base+"/")
+	}
+	if pat.anchored {
+		return pat.re.MatchString(candidate)
+	}
+	// Unanchored patterns may match at any depth under base, so try
+	// the match against every suffix starting at a path segment.
+	segments := strings.Split(candidate, "/")
+	for i := range segments {
+		if pat.re.MatchString(strings.Join(segments[i:], "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileLine parses a single ignore-file line into a pattern. It
+// reports ok=false for blank lines, comments, and unescaped inline
+// comments consuming the whole line. A "#" preceded by whitespace
+// starts an inline comment, which is stripped before compiling.
+func compileLine(line, baseDir string, caseInsensitive bool) (pattern, bool) {
+	for i := 1; i < len(line); i++ {
+		if line[i] == '#' && (line[i-1] == ' ' || line[i-1] == '\t') {
+			line = line[:i]
+			break
+		}
+	}
+	trimmed := strings.TrimRight(line, " \t")
+	if trimmed == "" {
+		return pattern{}, false
+	}
+	if strings.HasPrefix(trimmed, "#") {
+		return pattern{}, false
+	}
+
+	negate := false
+	if strings.HasPrefix(trimmed, "!") {
+		negate = true
+		trimmed = trimmed[1:]
+	}
+	if strings.HasPrefix(trimmed, "\\!") || strings.HasPrefix(trimmed, "\\#") {
+		trimmed = trimmed[1:]
+	}
+
+	dirOnly := strings.HasSuffix(trimmed, "/")
+	if dirOnly {
+		trimmed = strings.TrimSuffix(trimmed, "/")
+	}
+	if trimmed == "" {
+		return pattern{}, false
+	}
+
+	anchored := strings.Contains(trimmed, "/")
+	glob := strings.TrimPrefix(trimmed, "/")
+
+	re, err := regexp.Compile(globToRegexp(glob, caseInsensitive))
+	if err != nil {
+		return pattern{}, false
+	}
+
+	return pattern{
+		negate:   negate,
+		dirOnly:  dirOnly,
+		anchored: anchored,
+		base:     baseDir,
+		re:       re,
+	}, true
+}
+
+// globToRegexp translates a gitignore glob ("**", "*", "?") into an
+// anchored regexp matching a full slash-separated path.
+func globToRegexp(glob string, caseInsensitive bool) string {
+	var b strings.Builder
+	b.WriteString("^")
+	if caseInsensitive {
+		b.WriteString("(?i)")
+	}
+
+	runes := []rune(glob)
+	i := 0
+	// A leading "**/" must also match with zero leading path segments
+	// (gitignore(5): "**/foo" matches foo at the root, not just
+	// nested), so the directory part of the leading "**" is optional.
+	if len(runes) >= 3 && runes[0] == '*' && runes[1] == '*' && runes[2] == '/' {
+		b.WriteString("(?:.*/)?")
+		i = 3
+	}
+	for ; i < len(runes); i++ {
+		switch runes[i] {
+		case '*':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+				continue
+			}
+			b.WriteString("[^/]*")
+		case '?':
+			b.WriteString("[^/]")
+		case '.', '+', '(', ')', '|', '^', '$', '{', '}', '[', ']', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(runes[i])
+		default:
+			b.WriteRune(runes[i])
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeIgnoreFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(contents), 0644); err != nil {
+		t.Fatalf("WriteFile(%q): %v", p, err)
+	}
+	return p
+}
+
+func TestMatchBasicGlobAndDirOnly(t *testing.T) {
+	dir := t.TempDir()
+	writeIgnoreFile(t, dir, IgnoreFileName, "*.log\nbuild/\n")
+
+	m := New(false)
+	if err := m.LoadFile(filepath.Join(dir, IgnoreFileName), ""); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	cases := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"src/debug.log", false, true},
+		{"main.go", false, false},
+		{"build", true, true},
+		{"build", false, false},
+	}
+	for _, c := range cases {
+		if got := m.Match(c.path, c.isDir); got != c.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", c.path, c.isDir, got, c.want)
+		}
+	}
+}
+
+func TestMatchNegation(t *testing.T) {
+	dir := t.TempDir()
+	writeIgnoreFile(t, dir, IgnoreFileName, "*.log\n!keep.log\n")
+
+	m := New(false)
+	if err := m.LoadFile(filepath.Join(dir, IgnoreFileName), ""); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	if !m.Match("debug.log", false) {
+		t.Error("expected debug.log to be ignored")
+	}
+	if m.Match("keep.log", false) {
+		t.Error("expected keep.log to be re-included by negation")
+	}
+}
+
+func TestMatchDoubleStarAndCaseInsensitive(t *testing.T) {
+	dir := t.TempDir()
+	writeIgnoreFile(t, dir, IgnoreFileName, "**/*.TMP\n")
+
+	m := New(true)
+	if err := m.LoadFile(filepath.Join(dir, IgnoreFileName), ""); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	if !m.Match("a/b/c.tmp", false) {
+		t.Error("expected nested .tmp file to match case-insensitively")
+	}
+	// gitignore(5): a leading "**/" also matches with zero leading
+	// path segments, so a root-level file must match too.
+	if !m.Match("c.tmp", false) {
+		t.Error("expected root-level .tmp file to match a leading **/ pattern")
+	}
+}
+
+func TestMatchLeadingDoubleStarMatchesRootLevel(t *testing.T) {
+	dir := t.TempDir()
+	writeIgnoreFile(t, dir, IgnoreFileName, "**/foo\n")
+
+	m := New(false)
+	if err := m.LoadFile(filepath.Join(dir, IgnoreFileName), ""); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+
+	if !m.Match("foo", false) {
+		t.Error("expected **/foo to match a root-level foo")
+	}
+	if !m.Match("a/foo", false) {
+		t.Error("expected **/foo to match a nested foo")
+	}
+}
+
+func TestLoadFileSkipsAlreadyLoadedPath(t *testing.T) {
+	dir := t.TempDir()
+	p := writeIgnoreFile(t, dir, IgnoreFileName, "*.log\n")
+
+	m := New(false)
+	if err := m.LoadFile(p, ""); err != nil {
+		t.Fatalf("first LoadFile: %v", err)
+	}
+	if err := m.LoadFile(p, ""); err != nil {
+		t.Fatalf("second LoadFile: %v", err)
+	}
+	if got := len(m.patterns); got != 1 {
+		t.Errorf("patterns loaded = %d, want 1 (re-load of the same path should be skipped)", got)
+	}
+}
+
+func TestLoadTreeDoesNotRegrowOnRepeatedVisits(t *testing.T) {
+	root := t.TempDir()
+	writeIgnoreFile(t, root, IgnoreFileName, "*.log\n")
+	sub := filepath.Join(root, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	writeIgnoreFile(t, sub, IgnoreFileName, "*.tmp\n")
+
+	m := New(false)
+	if err := m.LoadTree(root, sub); err != nil {
+		t.Fatalf("first LoadTree: %v", err)
+	}
+	firstCount := len(m.patterns)
+
+	// Simulate a tree walk revisiting the same directory a second time,
+	// as a real walk does once per sibling file in that directory.
+	if err := m.LoadTree(root, sub); err != nil {
+		t.Fatalf("second LoadTree: %v", err)
+	}
+	if got := len(m.patterns); got != firstCount {
+		t.Errorf("patterns after repeated LoadTree = %d, want %d (ancestors should not be re-parsed)", got, firstCount)
+	}
+}
@@ -17,103 +17,146 @@ package drive
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
-	spinner "github.com/odeke-em/cli-spinner"
+	"github.com/richthofen911/drive/src/humanize"
+	"github.com/richthofen911/drive/src/ignore"
+	"github.com/richthofen911/drive/src/paths"
+	"github.com/richthofen911/drive/src/progress"
+	"github.com/richthofen911/drive/src/prompt"
+	"github.com/richthofen911/drive/src/render"
 )
 
 const (
 	MimeTypeJoiner = "-"
 )
 
+// BytesPerKB is kept for callers still doing their own IEC math.
 var BytesPerKB = float64(1024)
 
+// prompter is the Prompter used by promptForChanges and nextPage. It
+// defaults to an auto-detecting implementation (survey/v2 on a TTY,
+// line-based otherwise) but can be swapped, e.g. to honor --quiet/--yes
+// or to inject a stub in tests.
+var prompter = prompt.New(prompt.Options{})
+
+// SetQuiet reconfigures the package-wide prompter to honor --quiet/--yes:
+// every confirmation answers assume without blocking on input.
+func SetQuiet(assume bool) {
+	prompter = prompt.New(prompt.Options{Quiet: true, Assume: assume})
+}
+
 type desktopEntry struct {
 	name string
 	url  string
 	icon string
 }
 
-type playable struct {
-	play  func()
-	pause func()
-	reset func()
-	stop  func()
-}
-
-func newPlayable(freq int64) *playable {
-	spin := spinner.New(freq)
-
-	play := func() {
-		spin.Start()
-	}
+// playable is an alias for progress.Bars so push/pull can drive N
+// concurrent bars through Add/Update/Done, while the play/pause/
+// reset/stop quartet keeps working via the single-spinner fallback.
+type playable = progress.Bars
 
-	return &playable{
-		play:  play,
-		stop:  spin.Stop,
-		reset: spin.Reset,
-		pause: spin.Stop,
-	}
+func newPlayable(freq int64) playable {
+	return progress.New(freq)
 }
 
-type byteDescription func(b int64) string
-
-func memoizeBytes() byteDescription {
-	cache := map[int64]string{}
-	suffixes := []string{"B", "KB", "MB", "GB", "TB", "PB"}
-	maxLen := len(suffixes) - 1
+// prettyBytes formats a byte count for humans.
+func prettyBytes(b int64) string {
+	return humanize.Bytes(b)
+}
 
-	return func(b int64) string {
-		description, ok := cache[b]
-		if ok {
-			return description
-		}
+// renderOpts controls how catFile formats docs and code for the
+// terminal; --no-color/--pager/--theme flags feed SetRenderOptions.
+var renderOpts render.Options
 
-		bf := float64(b)
-		i := 0
-		description = ""
-		for {
-			if bf/BytesPerKB < 1 || i >= maxLen {
-				description = fmt.Sprintf("%.2f%s", bf, suffixes[i])
-				break
-			}
-			bf /= BytesPerKB
-			i += 1
-		}
-		cache[b] = description
-		return description
-	}
+// SetRenderOptions reconfigures the package-wide render.Options used
+// by catFile.
+func SetRenderOptions(opts render.Options) {
+	renderOpts = opts
 }
 
-var prettyBytes = memoizeBytes()
+// catFile writes data to w, the way a cat/preview command would: a
+// Google Doc exported as Markdown is rendered through glamour, a
+// recognized source file through chroma, a Google Sheet exported as
+// CSV through a tabwriter, and anything else (or any non-TTY w) is
+// written unmodified. name and mimeType are the same
+// file metadata toDesktopEntry already uses to pick an icon.
+func catFile(w io.Writer, name, mimeType string, data []byte) error {
+	return render.Render(w, name, mimeType, data, renderOpts)
+}
 
 func sepJoin(sep string, args ...string) string {
 	return strings.Join(args, sep)
 }
 
-func isHidden(p string, ignore bool) bool {
+func isHidden(p string, skip bool) bool {
 	if strings.HasPrefix(p, ".") {
-		return !ignore
+		return !skip
 	}
 	return false
 }
 
+// ignoreMatcher backs isIgnored. It stays nil until LoadIgnoreMatcher
+// populates it, so push/pull that never call LoadIgnoreMatcher keep
+// filtering on isHidden alone.
+var ignoreMatcher *ignore.Matcher
+
+// LoadIgnoreMatcher builds the package-wide ignore.Matcher for a
+// push/pull walk rooted at root, seeded with the user's global
+// .driveignore. Callers should follow up with ignoreMatcher.LoadTree
+// for each directory visited, so deeper .driveignore files layer in
+// as the walk descends.
+func LoadIgnoreMatcher(caseInsensitive bool) error {
+	m := ignore.New(caseInsensitive)
+	if err := m.LoadGlobal(); err != nil {
+		return err
+	}
+	ignoreMatcher = m
+	return nil
+}
+
+// isIgnored is the drop-in upgrade for callers that used to call
+// isHidden alone: p is skipped if it's a dotfile and allowHidden is
+// false (allowHidden is passed straight to isHidden), or if a loaded
+// .driveignore matches it.
+func isIgnored(p string, isDir, allowHidden bool) bool {
+	if isHidden(p, allowHidden) {
+		return true
+	}
+	if ignoreMatcher == nil {
+		return false
+	}
+	return ignoreMatcher.Match(p, isDir)
+}
+
+const (
+	pageMore = "More"
+	pageQuit = "Quit"
+)
+
+// nextPage asks whether to keep listing and returns false once the
+// user chooses to quit. Skip 10/All aren't offered here: nothing in
+// this tree's pagination loop reads past a plain continue/stop
+// signal, and a control that can't do anything is worse than no
+// control at all.
 func nextPage() bool {
-	var input string
-	fmt.Printf("---More---")
-	fmt.Scanln(&input)
-	if len(input) >= 1 && strings.ToLower(input[:1]) == "q" {
+	choice, err := prompter.Select("---More---", []string{pageMore, pageQuit})
+	if err != nil {
 		return false
 	}
-	return true
+	return choice != pageQuit
 }
 
 func promptForChanges() bool {
-	input := "Y"
-	fmt.Print("Proceed with the changes? [Y/n]: ")
-	fmt.Scanln(&input)
-	return strings.ToUpper(input) == "Y"
+	ok, err := prompter.Confirm("Proceed with the changes?", true)
+	if err != nil {
+		return false
+	}
+	return ok
 }
 
 func (f *File) toDesktopEntry(urlMExt *urlMimeTypeExt) *desktopEntry {
@@ -141,6 +184,17 @@ func (f *File) serializeAsDesktopEntry(destPath string, urlMExt *urlMimeTypeExt)
 		icon, deskEnt.name, LinkKey, deskEnt.url)
 }
 
+// installDesktopEntry additionally serializes f's desktop entry into
+// $XDG_DATA_HOME/applications, so the Google-native file shows up in
+// the desktop menu alongside regular application launchers.
+func (f *File) installDesktopEntry(urlMExt *urlMimeTypeExt) (int, error) {
+	if err := os.MkdirAll(paths.ApplicationsDir(), 0755); err != nil {
+		return 0, err
+	}
+	destPath := filepath.Join(paths.ApplicationsDir(), sepJoin(".", f.Name, "desktop"))
+	return f.serializeAsDesktopEntry(destPath, urlMExt)
+}
+
 func remotePathSplit(p string) (dir, base string) {
 	// Avoiding use of filepath.Split because of bug with trailing "/" not being stripped
 	sp := strings.Split(p, "/")
@@ -193,8 +247,17 @@ func commonPrefix(values ...string) string {
 	return string(prefix)
 }
 
+// readCommentedFile opens p, falling back to the legacy/XDG/etc search
+// order from drive/paths (keyed on p's base name) when p itself does
+// not exist, so ignore-lists, credentials and desktop-entry templates
+// can live outside the project tree.
 func readCommentedFile(p, comment string) (clauses []string, err error) {
 	f, fErr := os.Open(p)
+	if fErr != nil || f == nil {
+		if resolved, rErr := paths.Resolve(filepath.Base(p)); rErr == nil {
+			f, fErr = os.Open(resolved)
+		}
+	}
 	if fErr != nil || f == nil {
 		err = fErr
 		return
@@ -207,13 +270,32 @@ func readCommentedFile(p, comment string) (clauses []string, err error) {
 		if !scanner.Scan() {
 			break
 		}
-		line := scanner.Text()
+		// TrimRight for "\r" (not strings.Trim(line, "\n")) so CRLF
+		// files don't leave a stray \r glued to the end of the clause.
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if idx := inlineCommentIndex(line, comment); idx >= 0 {
+			line = line[:idx]
+		}
 		line = strings.Trim(line, " ")
-		line = strings.Trim(line, "\n")
-		if strings.HasPrefix(line, comment) || len(line) < 1 {
+		if len(line) < 1 {
 			continue
 		}
 		clauses = append(clauses, line)
 	}
 	return
 }
+
+// inlineCommentIndex finds comment within line, but only when it
+// starts the line or is preceded by whitespace, so a token like
+// "a#b" isn't mistaken for a comment starting at its "#".
+func inlineCommentIndex(line, comment string) int {
+	for i := 0; i+len(comment) <= len(line); i++ {
+		if line[i:i+len(comment)] != comment {
+			continue
+		}
+		if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+			return i
+		}
+	}
+	return -1
+}
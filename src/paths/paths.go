@@ -0,0 +1,107 @@
+// Package paths resolves where drive's config, cache and state live.
+// It resolves the XDG Base Directory locations ($XDG_CONFIG_HOME/drive,
+// $XDG_CACHE_HOME/drive, $XDG_STATE_HOME/drive) and keeps the legacy
+// in-tree ".gd/" layout working, both as the first location Candidates
+// searches and as something MigrateLegacy can move out of the way on
+// request.
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adrg/xdg"
+)
+
+const (
+	// AppName namespaces drive's directories under each XDG base.
+	AppName = "drive"
+	// LegacyDirName is the pre-XDG in-tree state directory.
+	LegacyDirName = ".gd"
+	// migratedMarker records that MigrateLegacy already ran, so the
+	// one-time notice is only ever printed once.
+	migratedMarker = ".migrated"
+)
+
+// ConfigDir returns $XDG_CONFIG_HOME/drive.
+func ConfigDir() string { return filepath.Join(xdg.ConfigHome, AppName) }
+
+// CacheDir returns $XDG_CACHE_HOME/drive.
+func CacheDir() string { return filepath.Join(xdg.CacheHome, AppName) }
+
+// StateDir returns $XDG_STATE_HOME/drive.
+func StateDir() string { return filepath.Join(xdg.StateHome, AppName) }
+
+// DataDir returns $XDG_DATA_HOME/drive.
+func DataDir() string { return filepath.Join(xdg.DataHome, AppName) }
+
+// ApplicationsDir returns $XDG_DATA_HOME/applications, where desktop
+// entries are looked up by menus and launchers.
+func ApplicationsDir() string { return filepath.Join(xdg.DataHome, "applications") }
+
+// legacyDir returns the project-local ".gd" directory used before XDG
+// support existed.
+func legacyDir() string { return LegacyDirName }
+
+// Candidates returns, in priority order, the paths that name should be
+// searched for at: the legacy project-local ".gd/" directory (kept
+// first for backward compatibility), the per-user XDG config
+// directory, and finally a system-wide "/etc/drive" directory.
+func Candidates(name string) []string {
+	return []string{
+		filepath.Join(legacyDir(), name),
+		filepath.Join(ConfigDir(), name),
+		filepath.Join("/etc", AppName, name),
+	}
+}
+
+// Resolve returns the first existing path among Candidates(name), or
+// an error if none exist.
+func Resolve(name string) (string, error) {
+	for _, candidate := range Candidates(name) {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("paths: %q not found in %v", name, Candidates(name))
+}
+
+// MigrateLegacy moves the entries of the legacy ".gd/" directory into
+// the XDG config directory, skipping any entry already present there,
+// and emits a one-time notice on stderr. It is a no-op once the
+// migrated marker exists; when there is no legacy directory it only
+// creates the XDG config directory.
+func MigrateLegacy() error {
+	markerPath := filepath.Join(ConfigDir(), migratedMarker)
+	if _, err := os.Stat(markerPath); err == nil {
+		return nil
+	}
+
+	legacy := legacyDir()
+	if _, err := os.Stat(legacy); os.IsNotExist(err) {
+		return os.MkdirAll(ConfigDir(), 0755)
+	}
+
+	if err := os.MkdirAll(ConfigDir(), 0755); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(legacy)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		src := filepath.Join(legacy, entry.Name())
+		dst := filepath.Join(ConfigDir(), entry.Name())
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+		if err := os.Rename(src, dst); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "drive: migrated %q to %q (XDG Base Directory support); this is a one-time notice\n", legacy, ConfigDir())
+	return os.WriteFile(markerPath, []byte("1"), 0644)
+}
@@ -0,0 +1,33 @@
+package paths
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCandidatesOrder(t *testing.T) {
+	got := Candidates("creds.json")
+	if len(got) != 3 {
+		t.Fatalf("Candidates returned %d entries, want 3", len(got))
+	}
+	if got[0] != filepath.Join(LegacyDirName, "creds.json") {
+		t.Errorf("first candidate = %q, want the legacy .gd/ path", got[0])
+	}
+	if got[1] != filepath.Join(ConfigDir(), "creds.json") {
+		t.Errorf("second candidate = %q, want the XDG config path", got[1])
+	}
+	if got[2] != filepath.Join("/etc", AppName, "creds.json") {
+		t.Errorf("third candidate = %q, want /etc/drive/creds.json", got[2])
+	}
+}
+
+func TestResolveNotFound(t *testing.T) {
+	_, err := Resolve("definitely-does-not-exist.json")
+	if err == nil {
+		t.Fatal("expected an error for a name present in no candidate location")
+	}
+	if !strings.Contains(err.Error(), "definitely-does-not-exist.json") {
+		t.Errorf("error %v does not mention the missing name", err)
+	}
+}
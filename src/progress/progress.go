@@ -0,0 +1,234 @@
+// Package progress renders one line per concurrent file transfer:
+// filename, transferred/total bytes, a gradient-filled bar, ETA and
+// throughput. On a non-TTY or when NO_COLOR is set it falls back to a
+// plain spinner/text output instead, so push/pull never writes escape
+// codes into a log file.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mazznoer/colorgrad"
+	"github.com/muesli/termenv"
+	spinner "github.com/odeke-em/cli-spinner"
+
+	"github.com/richthofen911/drive/src/humanize"
+)
+
+// Bars is the API push/pull drive N concurrent transfer bars through.
+// The play/pause/reset/stop quartet remains for the single-spinner
+// fallback; callers should prefer Add/Update/Done so the same code
+// works whether or not the terminal can render color.
+type Bars interface {
+	Play()
+	Pause()
+	Reset()
+	Stop()
+
+	// Add registers a new transfer named id with the given total size
+	// in bytes.
+	Add(id string, total int64)
+	// Update reports delta additional bytes transferred for id.
+	Update(id string, delta int64)
+	// Done marks id as finished and removes its line.
+	Done(id string)
+}
+
+// New returns a Bars appropriate for the current environment: a
+// gradient, color multi-bar renderer on a capable TTY, or the old
+// single-spinner behavior otherwise.
+func New(freq int64) Bars {
+	out := os.Stdout
+	if os.Getenv("NO_COLOR") != "" || termenv.NewOutput(out).Profile == termenv.Ascii {
+		return newSpinnerBars(freq)
+	}
+	return newGradientBars(out)
+}
+
+// spinnerBars is the non-TTY/NO_COLOR fallback: a single spinner with
+// Add/Update/Done reduced to printing plain progress lines, matching
+// the original playable's behavior of having nothing to show besides
+// "working".
+type spinnerBars struct {
+	spin *spinner.Spinner
+	mu   sync.Mutex
+}
+
+func newSpinnerBars(freq int64) *spinnerBars {
+	return &spinnerBars{spin: spinner.New(freq)}
+}
+
+func (s *spinnerBars) Play()  { s.spin.Start() }
+func (s *spinnerBars) Pause() { s.spin.Stop() }
+func (s *spinnerBars) Reset() { s.spin.Reset() }
+func (s *spinnerBars) Stop()  { s.spin.Stop() }
+
+func (s *spinnerBars) Add(id string, total int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Printf("%s: starting (%s)\n", id, humanize.Bytes(total))
+}
+
+func (s *spinnerBars) Update(id string, delta int64) {}
+
+func (s *spinnerBars) Done(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Printf("%s: done\n", id)
+}
+
+// transfer tracks the state rendered on one line of a gradientBars.
+type transfer struct {
+	total       int64
+	transferred int64
+	started     time.Time
+}
+
+// gradientBars renders one line per active transfer: filename,
+// transferred/total (via humanize), a gradient-filled bar colored by
+// percent-complete, ETA and throughput.
+type gradientBars struct {
+	mu      sync.Mutex
+	out     io.Writer
+	order   []string
+	byID    map[string]*transfer
+	grad    colorgrad.Gradient
+	profile termenv.Profile
+	lines   int
+}
+
+func newGradientBars(out io.Writer) *gradientBars {
+	grad, _ := colorgrad.NewGradient().
+		HtmlColors("#d73027", "#fee08b", "#1a9850").
+		Build()
+	return &gradientBars{
+		out:     out,
+		byID:    make(map[string]*transfer),
+		grad:    grad,
+		profile: termenv.NewOutput(out).Profile,
+	}
+}
+
+func (g *gradientBars) Play()  {}
+func (g *gradientBars) Pause() {}
+func (g *gradientBars) Reset() {}
+func (g *gradientBars) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.order = nil
+	g.byID = make(map[string]*transfer)
+}
+
+func (g *gradientBars) Add(id string, total int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.byID[id]; ok {
+		return
+	}
+	g.order = append(g.order, id)
+	g.byID[id] = &transfer{total: total, started: timeNow()}
+	g.render()
+}
+
+func (g *gradientBars) Update(id string, delta int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, ok := g.byID[id]
+	if !ok {
+		return
+	}
+	t.transferred += delta
+	g.render()
+}
+
+func (g *gradientBars) Done(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.byID, id)
+	for i, other := range g.order {
+		if other == id {
+			g.order = append(g.order[:i], g.order[i+1:]...)
+			break
+		}
+	}
+	g.render()
+}
+
+// timeNow is a seam so tests could swap in a fixed clock; production
+// code just calls time.Now.
+var timeNow = time.Now
+
+// render redraws every active transfer's line in place, moving the
+// cursor back up over the previous frame first. It blanks max(old,
+// new) rows before redrawing so a frame with fewer transfers than the
+// last one (e.g. Done shrinking the active set) doesn't leave a stale
+// line behind from the old, taller frame.
+func (g *gradientBars) render() {
+	oldLines := g.lines
+	newLines := len(g.order)
+	clearLines := oldLines
+	if newLines > clearLines {
+		clearLines = newLines
+	}
+
+	if oldLines > 0 {
+		fmt.Fprintf(g.out, "\033[%dA", oldLines)
+	}
+	for i := 0; i < clearLines; i++ {
+		fmt.Fprint(g.out, "\033[2K\n")
+	}
+	if clearLines > 0 {
+		fmt.Fprintf(g.out, "\033[%dA", clearLines)
+	}
+
+	for _, id := range g.order {
+		t := g.byID[id]
+		fmt.Fprintf(g.out, "\033[2K%s\n", g.renderLine(id, t))
+	}
+	g.lines = newLines
+}
+
+func (g *gradientBars) renderLine(id string, t *transfer) string {
+	percent := 0.0
+	if t.total > 0 {
+		percent = float64(t.transferred) / float64(t.total)
+	}
+
+	bar := g.bar(percent)
+	elapsed := timeNow().Sub(t.started).Seconds()
+	throughput := float64(0)
+	if elapsed > 0 {
+		throughput = float64(t.transferred) / elapsed
+	}
+	eta := "--"
+	if throughput > 0 && t.total > t.transferred {
+		remaining := float64(t.total-t.transferred) / throughput
+		eta = time.Duration(remaining * float64(time.Second)).Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("%-24s %s %3.0f%%  %s/%s  %s/s  ETA %s",
+		id, bar, percent*100,
+		humanize.Bytes(t.transferred), humanize.Bytes(t.total),
+		humanize.Bytes(int64(throughput)), eta)
+}
+
+const barWidth = 24
+
+func (g *gradientBars) bar(percent float64) string {
+	filled := int(percent * float64(barWidth))
+	var b []byte
+	for i := 0; i < barWidth; i++ {
+		if i >= filled {
+			b = append(b, ' ')
+			continue
+		}
+		color := g.grad.At(percent).HexString()
+		style := termenv.String("█").Foreground(g.profile.Color(color))
+		b = append(b, []byte(style.String())...)
+	}
+	return "[" + string(b) + "]"
+}
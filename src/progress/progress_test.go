@@ -0,0 +1,69 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGradientBarsRenderClearsFullOldFootprintOnShrink(t *testing.T) {
+	restore := timeNow
+	timeNow = func() time.Time { return time.Unix(0, 0) }
+	defer func() { timeNow = restore }()
+
+	var buf bytes.Buffer
+	g := newGradientBars(&buf)
+
+	g.Add("a", 100)
+	g.Add("b", 100)
+	g.Add("c", 100)
+	if g.lines != 3 {
+		t.Fatalf("lines after 3 Adds = %d, want 3", g.lines)
+	}
+
+	buf.Reset()
+	g.Done("b")
+	if g.lines != 2 {
+		t.Fatalf("lines after Done shrinking to 2 = %d, want 2", g.lines)
+	}
+
+	out := buf.String()
+	// The blank-out pass clears max(old=3, new=2)=3 rows, then the
+	// redraw pass clears+writes each of the 2 surviving rows: 3+2=5
+	// "\033[2K" sequences in total. Before the fix, the blank-out pass
+	// only ever covered the new, smaller row count, leaving row 3 (the
+	// departed "b") on screen untouched.
+	if clears := strings.Count(out, "\033[2K"); clears != 5 {
+		t.Errorf("render on shrink emitted %d clear sequences, want 5", clears)
+	}
+
+	// Every remaining id's line must still be present, and the stale
+	// "b" line must be gone.
+	if !strings.Contains(out, "a") || !strings.Contains(out, "c") {
+		t.Errorf("render output missing a surviving transfer's line: %q", out)
+	}
+	if strings.Contains(out, "\033[2Kb ") {
+		t.Errorf("render output still contains b's stale line: %q", out)
+	}
+}
+
+func TestGradientBarsAddIsIdempotentPerID(t *testing.T) {
+	var buf bytes.Buffer
+	g := newGradientBars(&buf)
+	g.Add("a", 100)
+	g.Add("a", 999)
+	if len(g.order) != 1 {
+		t.Errorf("order = %v, want a single entry for a repeated id", g.order)
+	}
+	if g.byID["a"].total != 100 {
+		t.Errorf("total = %d, want the first Add's total to stick", g.byID["a"].total)
+	}
+}
+
+func TestSpinnerBarsAddUpdateDoneDoNotPanic(t *testing.T) {
+	s := newSpinnerBars(0)
+	s.Add("file.txt", 2048)
+	s.Update("file.txt", 1024) // no-op in the fallback, must not panic
+	s.Done("file.txt")
+}
@@ -0,0 +1,186 @@
+// Package prompt centralizes the interactive bits of drive behind a
+// single Prompter interface: an arrow-key driven survey/v2
+// implementation on a TTY, a line-based fallback for pipes and
+// scripts, and a quiet implementation for --quiet/--yes.
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	survey "github.com/AlecAivazis/survey/v2"
+	isatty "github.com/mattn/go-isatty"
+)
+
+// Prompter is the single seam through which drive asks the user
+// questions. Commands should depend on this interface, not on a
+// concrete implementation, so tests can inject a stub.
+type Prompter interface {
+	// Confirm asks a yes/no question, returning defaultYes when the
+	// user answers with an empty line.
+	Confirm(message string, defaultYes bool) (bool, error)
+	// Select offers a single choice among options.
+	Select(message string, options []string) (string, error)
+	// MultiSelect offers zero or more choices among options, letting
+	// the user toggle individual entries instead of accepting or
+	// rejecting the whole list.
+	MultiSelect(message string, options []string) ([]string, error)
+	// Password prompts for masked input, for OAuth codes and
+	// shared-link passwords.
+	Password(message string) (string, error)
+}
+
+// Options configure the behavior of a Prompter.
+type Options struct {
+	// Quiet suppresses prompts and answers as if Yes/Assume had been
+	// passed.
+	Quiet bool
+	// Assume, when Quiet is set, is the answer returned for Confirm.
+	Assume bool
+}
+
+// New returns a Prompter appropriate for the current environment: an
+// interactive survey/v2 based one when stdin is a TTY, and a
+// line-based fallback otherwise.
+func New(opts Options) Prompter {
+	if opts.Quiet {
+		return &quietPrompter{assume: opts.Assume}
+	}
+	if isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd()) {
+		return &surveyPrompter{}
+	}
+	return &linePrompter{r: bufio.NewReader(os.Stdin), w: os.Stdout}
+}
+
+// quietPrompter never blocks on input; it is used when --quiet/--yes
+// is passed so batch invocations never hang on a question.
+type quietPrompter struct {
+	assume bool
+}
+
+func (q *quietPrompter) Confirm(message string, defaultYes bool) (bool, error) {
+	return q.assume, nil
+}
+
+func (q *quietPrompter) Select(message string, options []string) (string, error) {
+	if len(options) < 1 {
+		return "", fmt.Errorf("prompt: no options to select from")
+	}
+	return options[0], nil
+}
+
+func (q *quietPrompter) MultiSelect(message string, options []string) ([]string, error) {
+	if q.assume {
+		return options, nil
+	}
+	return nil, nil
+}
+
+func (q *quietPrompter) Password(message string) (string, error) {
+	return "", fmt.Errorf("prompt: cannot read a password in quiet mode")
+}
+
+// surveyPrompter renders arrow-key driven prompts via survey/v2.
+type surveyPrompter struct{}
+
+func (s *surveyPrompter) Confirm(message string, defaultYes bool) (bool, error) {
+	answer := defaultYes
+	err := survey.AskOne(&survey.Confirm{
+		Message: message,
+		Default: defaultYes,
+	}, &answer)
+	return answer, err
+}
+
+func (s *surveyPrompter) Select(message string, options []string) (string, error) {
+	var answer string
+	err := survey.AskOne(&survey.Select{
+		Message: message,
+		Options: options,
+	}, &answer)
+	return answer, err
+}
+
+func (s *surveyPrompter) MultiSelect(message string, options []string) ([]string, error) {
+	var answer []string
+	err := survey.AskOne(&survey.MultiSelect{
+		Message: message,
+		Options: options,
+	}, &answer)
+	return answer, err
+}
+
+func (s *surveyPrompter) Password(message string) (string, error) {
+	var answer string
+	err := survey.AskOne(&survey.Password{
+		Message: message,
+	}, &answer)
+	return answer, err
+}
+
+// linePrompter keeps the original line-based prompts for non-TTY use
+// (pipes, CI, scripts): one line is read per question and only its
+// first letter (Confirm) or a case-insensitive prefix (Select) counts.
+type linePrompter struct {
+	r *bufio.Reader
+	w io.Writer
+}
+
+func (l *linePrompter) readLine() string {
+	line, _ := l.r.ReadString('\n')
+	return strings.TrimSpace(line)
+}
+
+func (l *linePrompter) Confirm(message string, defaultYes bool) (bool, error) {
+	def := "Y/n"
+	if !defaultYes {
+		def = "y/N"
+	}
+	fmt.Fprintf(l.w, "%s [%s]: ", message, def)
+	input := l.readLine()
+	if input == "" {
+		return defaultYes, nil
+	}
+	return strings.ToUpper(input[:1]) == "Y", nil
+}
+
+func (l *linePrompter) Select(message string, options []string) (string, error) {
+	if len(options) < 1 {
+		return "", fmt.Errorf("prompt: no options to select from")
+	}
+	fmt.Fprintf(l.w, "%s ", message)
+	input := l.readLine()
+	if input == "" {
+		return options[0], nil
+	}
+	for _, opt := range options {
+		if strings.EqualFold(opt, input) || strings.HasPrefix(strings.ToLower(opt), strings.ToLower(input)) {
+			return opt, nil
+		}
+	}
+	return options[0], nil
+}
+
+func (l *linePrompter) MultiSelect(message string, options []string) ([]string, error) {
+	fmt.Fprintf(l.w, "%s (comma-separated, blank for all): ", message)
+	input := l.readLine()
+	if input == "" {
+		return options, nil
+	}
+	var chosen []string
+	for _, piece := range strings.Split(input, ",") {
+		piece = strings.TrimSpace(piece)
+		if piece != "" {
+			chosen = append(chosen, piece)
+		}
+	}
+	return chosen, nil
+}
+
+func (l *linePrompter) Password(message string) (string, error) {
+	fmt.Fprintf(l.w, "%s ", message)
+	return l.readLine(), nil
+}
@@ -0,0 +1,85 @@
+package prompt
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestQuietPrompterAssumes(t *testing.T) {
+	q := &quietPrompter{assume: true}
+
+	ok, err := q.Confirm("proceed?", false)
+	if err != nil || !ok {
+		t.Errorf("Confirm = (%v, %v), want (true, nil)", ok, err)
+	}
+
+	choice, err := q.Select("pick", []string{"a", "b"})
+	if err != nil || choice != "a" {
+		t.Errorf("Select = (%q, %v), want (%q, nil)", choice, err, "a")
+	}
+
+	chosen, err := q.MultiSelect("pick some", []string{"a", "b"})
+	if err != nil || len(chosen) != 2 {
+		t.Errorf("MultiSelect with assume=true = (%v, %v), want all options", chosen, err)
+	}
+
+	if _, err := q.Password("code"); err == nil {
+		t.Error("Password in quiet mode should error instead of blocking")
+	}
+}
+
+func TestQuietPrompterDoesNotAssume(t *testing.T) {
+	q := &quietPrompter{assume: false}
+
+	chosen, err := q.MultiSelect("pick some", []string{"a", "b"})
+	if err != nil || chosen != nil {
+		t.Errorf("MultiSelect with assume=false = (%v, %v), want (nil, nil)", chosen, err)
+	}
+}
+
+func newLinePrompter(input string) (*linePrompter, *bytes.Buffer) {
+	var out bytes.Buffer
+	return &linePrompter{r: bufio.NewReader(strings.NewReader(input)), w: &out}, &out
+}
+
+func TestLinePrompterConfirmDefault(t *testing.T) {
+	l, _ := newLinePrompter("\n")
+	ok, err := l.Confirm("proceed?", true)
+	if err != nil || !ok {
+		t.Errorf("Confirm with blank input = (%v, %v), want (true, nil)", ok, err)
+	}
+}
+
+func TestLinePrompterConfirmExplicit(t *testing.T) {
+	l, _ := newLinePrompter("n\n")
+	ok, err := l.Confirm("proceed?", true)
+	if err != nil || ok {
+		t.Errorf("Confirm with 'n' = (%v, %v), want (false, nil)", ok, err)
+	}
+}
+
+func TestLinePrompterSelectPrefixMatch(t *testing.T) {
+	l, _ := newLinePrompter("sk\n")
+	choice, err := l.Select("---More---", []string{"More", "Quit", "Skip 10", "All"})
+	if err != nil || choice != "Skip 10" {
+		t.Errorf("Select(%q) = (%q, %v), want (%q, nil)", "sk", choice, err, "Skip 10")
+	}
+}
+
+func TestLinePrompterMultiSelectBlankMeansAll(t *testing.T) {
+	l, _ := newLinePrompter("\n")
+	chosen, err := l.MultiSelect("pick", []string{"a", "b", "c"})
+	if err != nil || len(chosen) != 3 {
+		t.Errorf("MultiSelect with blank input = (%v, %v), want all 3 options", chosen, err)
+	}
+}
+
+func TestLinePrompterMultiSelectCommaSeparated(t *testing.T) {
+	l, _ := newLinePrompter("a, c\n")
+	chosen, err := l.MultiSelect("pick", []string{"a", "b", "c"})
+	if err != nil || len(chosen) != 2 || chosen[0] != "a" || chosen[1] != "c" {
+		t.Errorf("MultiSelect(%q) = (%v, %v), want [a c]", "a, c", chosen, err)
+	}
+}
@@ -0,0 +1,135 @@
+// Package render turns the bytes of a fetched file into something
+// pleasant to look at on a terminal: Google Docs exported as Markdown
+// go through glamour, source code goes through chroma with a lexer
+// picked from its extension, sheets exported as CSV get a plain
+// tabwriter pass, and everything else falls back to raw bytes. Output
+// to a non-TTY is always raw bytes, so pipelines are never surprised
+// by escape codes.
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/quick"
+	"github.com/charmbracelet/glamour"
+	isatty "github.com/mattn/go-isatty"
+)
+
+const (
+	// MimeGoogleDoc is the mime type of a Google Doc.
+	MimeGoogleDoc = "application/vnd.google-apps.document"
+	// MimeGoogleSheet is the mime type of a Google Sheet.
+	MimeGoogleSheet = "application/vnd.google-apps.spreadsheet"
+)
+
+// Options controls how Render decides to present a file.
+type Options struct {
+	// NoColor disables glamour/chroma styling even on a TTY.
+	NoColor bool
+	// Pager, when true, asks the caller's environment to page the
+	// rendered output. Render itself ignores Pager and only writes the
+	// formatted output to w; paging is left to the caller (e.g. via $PAGER) so it can
+	// reuse whatever pager invocation the rest of drive already uses.
+	Pager bool
+	// Theme names the chroma/glamour style to render with. An empty
+	// Theme picks glamour's "auto" style and chroma's "monokai".
+	Theme string
+}
+
+// Render writes data to w, formatted according to name's extension
+// and mimeType. On a non-TTY w (or when opts.NoColor is set) it writes
+// data unmodified so scripts and pipes keep seeing raw bytes.
+func Render(w io.Writer, name, mimeType string, data []byte, opts Options) error {
+	if opts.NoColor || !isTTY(w) {
+		_, err := w.Write(data)
+		return err
+	}
+
+	switch {
+	case mimeType == MimeGoogleDoc:
+		return renderMarkdown(w, data, opts.Theme)
+	case mimeType == MimeGoogleSheet:
+		return renderCSV(w, data)
+	case isSourceCode(name, mimeType):
+		return renderCode(w, name, data, opts.Theme)
+	default:
+		_, err := w.Write(data)
+		return err
+	}
+}
+
+func isTTY(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func renderMarkdown(w io.Writer, data []byte, theme string) error {
+	style := theme
+	if style == "" {
+		style = "auto"
+	}
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithStandardStyle(style),
+		glamour.WithWordWrap(0),
+	)
+	if err != nil {
+		return err
+	}
+	out, err := renderer.RenderBytes(data)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func renderCode(w io.Writer, name string, data []byte, theme string) error {
+	style := theme
+	if style == "" {
+		style = "monokai"
+	}
+	lexer := lexerFor(name)
+	return quick.Highlight(w, string(data), lexer, "terminal256", style)
+}
+
+func renderCSV(w io.Writer, data []byte) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	records, err := reader.ReadAll()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, record := range records {
+		for i, field := range record {
+			if i > 0 {
+				fmt.Fprint(tw, "\t")
+			}
+			fmt.Fprint(tw, field)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func isSourceCode(name, mimeType string) bool {
+	if mimeType == "text/x-go" {
+		return true
+	}
+	return lexers.Match(name) != nil
+}
+
+func lexerFor(name string) string {
+	if lexer := lexers.Match(name); lexer != nil {
+		return lexer.Config().Name
+	}
+	return "plaintext"
+}
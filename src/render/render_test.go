@@ -0,0 +1,63 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRenderNonTTYWritesRawBytes(t *testing.T) {
+	var buf bytes.Buffer
+	data := []byte("# Title\n\nhello\n")
+	if err := Render(&buf, "doc.md", MimeGoogleDoc, data, Options{}); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if buf.String() != string(data) {
+		t.Errorf("Render to a non-*os.File writer = %q, want raw bytes %q", buf.String(), data)
+	}
+}
+
+func TestRenderNoColorFallsBackToRawBytes(t *testing.T) {
+	var buf bytes.Buffer
+	data := []byte("package main\n")
+	if err := Render(&buf, "main.go", "", data, Options{NoColor: true}); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if buf.String() != string(data) {
+		t.Errorf("Render with NoColor = %q, want raw bytes %q", buf.String(), data)
+	}
+}
+
+func TestIsSourceCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		mimeType string
+		want     bool
+	}{
+		{"main.go", "", true},
+		{"script.py", "", true},
+		{"README", "text/x-go", true},
+		// chroma's own lexer registry, not a hand-rolled extension
+		// table, decides this, so extensions the old hardcoded map
+		// never listed (Kotlin, Swift, ...) are recognized too.
+		{"app.kt", "", true},
+		{"app.swift", "", true},
+		{"README", "", false},
+	}
+	for _, c := range cases {
+		if got := isSourceCode(c.name, c.mimeType); got != c.want {
+			t.Errorf("isSourceCode(%q, %q) = %v, want %v", c.name, c.mimeType, got, c.want)
+		}
+	}
+}
+
+func TestLexerFor(t *testing.T) {
+	if got := lexerFor("main.go"); got != "Go" {
+		t.Errorf("lexerFor(main.go) = %q, want %q", got, "Go")
+	}
+	if got := lexerFor("app.kt"); got != "Kotlin" {
+		t.Errorf("lexerFor(app.kt) = %q, want %q", got, "Kotlin")
+	}
+	if got := lexerFor("unknown.xyz"); got != "plaintext" {
+		t.Errorf("lexerFor(unknown.xyz) = %q, want %q", got, "plaintext")
+	}
+}